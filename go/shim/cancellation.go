@@ -0,0 +1,75 @@
+// Copyright 2025 Vantage Compute
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "C"
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+)
+
+// opSeq hands out globally unique ids purely so two tenants' op ids never
+// collide in logs; the cancel funcs themselves live on the owning
+// configState (see configState.ops), not in a shared map, so one handle can
+// never reach into another handle's in-flight operations.
+var opSeq atomic.Uint64
+
+// beginOperation registers a cancellable context for a long-running action
+// on state's own operation registry. The returned id is handed back to
+// Python so a stuck operation can be interrupted with helmpy_cancel; the
+// context must be threaded through the underlying Helm action's Run call.
+func beginOperation(state *configState) (uint64, context.Context, context.CancelFunc) {
+	id := opSeq.Add(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	state.ops.Store(id, cancel)
+	return id, ctx, cancel
+}
+
+// endOperation removes a finished operation from state's registry. Safe to
+// call even if the operation was never looked up by helmpy_cancel.
+func endOperation(state *configState, id uint64) {
+	state.ops.Delete(id)
+}
+
+// emitOpID reports id through the registered log callback, synchronously and
+// before the action's blocking Run call. A single-threaded ctypes caller
+// cannot read op_id_out until the call returns, by which point the operation
+// has already finished; a second thread watching the log callback can pick
+// up the id in time to call helmpy_cancel on a still-running operation. It
+// is a no-op if no callback has been registered.
+func emitOpID(state *configState, id uint64) {
+	if state.logCB != nil {
+		state.logCB.emit(logLevelOpStarted, strconv.FormatUint(id, 10))
+	}
+}
+
+// Cancellation
+
+//export helmpy_cancel
+func helmpy_cancel(handle C.helmpy_handle, op_id C.longlong) C.int {
+	state, err := getConfig(handle)
+	if err != nil {
+		return setError(err)
+	}
+
+	cancelAny, ok := state.ops.Load(uint64(op_id))
+	if !ok {
+		return recordErrorf("unknown or already-finished operation id %d for this configuration", int64(op_id))
+	}
+	cancelAny.(context.CancelFunc)()
+	return 0
+}
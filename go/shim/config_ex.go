@@ -0,0 +1,162 @@
+// Copyright 2025 Vantage Compute
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "C"
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli"
+)
+
+// configCreateOptions is the full options struct accepted by
+// helmpy_config_create_ex, covering everything the plain positional-argument
+// helmpy_config_create cannot express.
+type configCreateOptions struct {
+	Namespace   string   `json:"namespace,omitempty"`
+	Kubeconfig  string   `json:"kubeconfig,omitempty"`
+	KubeContext string   `json:"kube_context,omitempty"`
+	Namespaces  []string `json:"namespaces,omitempty"`
+
+	Driver    string `json:"driver,omitempty"`
+	DriverDSN string `json:"driver_dsn,omitempty"`
+
+	Burst int     `json:"burst,omitempty"`
+	QPS   float32 `json:"qps,omitempty"`
+
+	// RepoUsername/RepoPassword/RepoCAFile authenticate private HTTP(S)
+	// chart repositories referenced by install/upgrade/template/diff; they
+	// are distinct from OCI registry login (see helmpy_registry_login).
+	RepoUsername string `json:"repo_username,omitempty"`
+	RepoPassword string `json:"repo_password,omitempty"`
+	RepoCAFile   string `json:"repo_ca_file,omitempty"`
+
+	ImpersonateUser   string   `json:"impersonate_user,omitempty"`
+	ImpersonateGroups []string `json:"impersonate_groups,omitempty"`
+
+	HTTPProxy string `json:"http_proxy,omitempty"`
+}
+
+// restConfigOverrideGetter wraps a RESTClientGetter and applies rate-limit,
+// impersonation, and proxy overrides to the rest.Config it produces.
+type restConfigOverrideGetter struct {
+	genericclioptions.RESTClientGetter
+	opts configCreateOptions
+}
+
+func (g *restConfigOverrideGetter) ToRESTConfig() (*rest.Config, error) {
+	restConfig, err := g.RESTClientGetter.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if g.opts.Burst > 0 {
+		restConfig.Burst = g.opts.Burst
+	}
+	if g.opts.QPS > 0 {
+		restConfig.QPS = g.opts.QPS
+	}
+	if g.opts.HTTPProxy != "" {
+		proxyURL, err := url.Parse(g.opts.HTTPProxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid http_proxy: %w", err)
+		}
+		restConfig.Proxy = func(*http.Request) (*url.URL, error) {
+			return proxyURL, nil
+		}
+	}
+	if g.opts.ImpersonateUser != "" {
+		restConfig.Impersonate = rest.ImpersonationConfig{
+			UserName: g.opts.ImpersonateUser,
+			Groups:   g.opts.ImpersonateGroups,
+		}
+	}
+
+	return restConfig, nil
+}
+
+// Extended configuration management
+
+//export helmpy_config_create_ex
+func helmpy_config_create_ex(options_json *C.char, handle_out *C.helmpy_handle) C.int {
+	optionsJSON := C.GoString(options_json)
+
+	var opts configCreateOptions
+	if optionsJSON != "" {
+		if err := json.Unmarshal([]byte(optionsJSON), &opts); err != nil {
+			return setError(fmt.Errorf("failed to parse options JSON: %w", err))
+		}
+	}
+
+	// Namespaces is a one-time allowlist check: envs.Namespace() below is
+	// fixed for the lifetime of the resulting configState (no action ever
+	// changes it), so rejecting a disallowed Namespace here is sufficient —
+	// there is no later per-action namespace to re-check against.
+	if len(opts.Namespaces) > 0 && opts.Namespace != "" {
+		allowed := false
+		for _, ns := range opts.Namespaces {
+			if ns == opts.Namespace {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return setError(fmt.Errorf("namespace %q is not in the configured namespace allowlist", opts.Namespace))
+		}
+	}
+
+	envs := cli.New()
+	if opts.Namespace != "" {
+		envs.SetNamespace(opts.Namespace)
+	}
+	if opts.KubeContext != "" {
+		envs.KubeContext = opts.KubeContext
+	}
+
+	// Same kubeconfig-as-YAML/in-cluster/file-path selection as
+	// helmpy_config_create, so multi-tenant configs get exec-plugin and
+	// in-cluster auth too, not just a plain kubeconfig file path.
+	getter := &restConfigOverrideGetter{
+		RESTClientGetter: selectRESTClientGetter(opts.Kubeconfig, opts.KubeContext, envs),
+		opts:             opts,
+	}
+
+	cfg := new(action.Configuration)
+	if err := initStorage(cfg, getter, envs.Namespace(), opts.Driver, opts.DriverDSN); err != nil {
+		return setError(fmt.Errorf("failed to initialize helm config: %w", err))
+	}
+
+	state := &configState{
+		cfg:          cfg,
+		envs:         envs,
+		repoUsername: opts.RepoUsername,
+		repoPassword: opts.RepoPassword,
+		repoCAFile:   opts.RepoCAFile,
+	}
+
+	handle := nextHandle()
+	configs.Store(handle, state)
+	*handle_out = handle
+
+	return 0
+}
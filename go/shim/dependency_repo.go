@@ -0,0 +1,332 @@
+// Copyright 2025 Vantage Compute
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "C"
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+
+	"helm.sh/helm/v4/pkg/downloader"
+	"helm.sh/helm/v4/pkg/getter"
+	"helm.sh/helm/v4/pkg/helmpath"
+	"helm.sh/helm/v4/pkg/repo"
+	"helm.sh/helm/v4/pkg/search"
+)
+
+// Dependency management
+
+//export helmpy_dependency_update
+func helmpy_dependency_update(handle C.helmpy_handle, chart_path *C.char, skip_refresh C.int) C.int {
+	state, err := getConfig(handle)
+	if err != nil {
+		return setError(err)
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	man := &downloader.Manager{
+		Out:              io.Discard,
+		ChartPath:        C.GoString(chart_path),
+		SkipUpdate:       skip_refresh != 0,
+		Getters:          getter.All(state.envs),
+		RegistryClient:   state.cfg.RegistryClient,
+		RepositoryConfig: state.envs.RepositoryConfig,
+		RepositoryCache:  state.envs.RepositoryCache,
+		Debug:            state.envs.Debug,
+	}
+
+	if err := man.Update(); err != nil {
+		return setError(fmt.Errorf("dependency update failed: %w", err))
+	}
+
+	return 0
+}
+
+//export helmpy_dependency_build
+func helmpy_dependency_build(handle C.helmpy_handle, chart_path *C.char) C.int {
+	state, err := getConfig(handle)
+	if err != nil {
+		return setError(err)
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	man := &downloader.Manager{
+		Out:              io.Discard,
+		ChartPath:        C.GoString(chart_path),
+		Getters:          getter.All(state.envs),
+		RegistryClient:   state.cfg.RegistryClient,
+		RepositoryConfig: state.envs.RepositoryConfig,
+		RepositoryCache:  state.envs.RepositoryCache,
+		Debug:            state.envs.Debug,
+	}
+
+	if err := man.Build(); err != nil {
+		return setError(fmt.Errorf("dependency build failed: %w", err))
+	}
+
+	return 0
+}
+
+// Repository management
+
+// loadRepoFile reads the repository config file, returning a fresh, empty
+// repo.File if it does not exist yet (mirrors the Helm CLI's repo commands).
+func loadRepoFile(path string) (*repo.File, error) {
+	r, err := repo.LoadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return repo.NewFile(), nil
+		}
+		return nil, err
+	}
+	return r, nil
+}
+
+//export helmpy_repo_add
+func helmpy_repo_add(handle C.helmpy_handle, name *C.char, url *C.char, username *C.char, password *C.char, ca_file *C.char, pass_credentials_all C.int) C.int {
+	state, err := getConfig(handle)
+	if err != nil {
+		return setError(err)
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	entry := repo.Entry{
+		Name:               C.GoString(name),
+		URL:                C.GoString(url),
+		Username:           C.GoString(username),
+		Password:           C.GoString(password),
+		CAFile:             C.GoString(ca_file),
+		PassCredentialsAll: pass_credentials_all != 0,
+	}
+
+	repoFile, err := loadRepoFile(state.envs.RepositoryConfig)
+	if err != nil {
+		return setError(fmt.Errorf("failed to load repository config: %w", err))
+	}
+
+	// Match `helm repo add`: an existing entry with the same name but a
+	// different URL is rejected rather than silently swapped out from
+	// under the caller. Re-adding the same name/URL pair (e.g. to rotate
+	// credentials) is allowed.
+	if existing := repoFile.Get(entry.Name); existing != nil && existing.URL != entry.URL {
+		return setError(fmt.Errorf("repository name %q already exists with URL %q, remove it first to point it at %q", entry.Name, existing.URL, entry.URL))
+	}
+
+	// Validate the repository by downloading its index, the same way
+	// `helm repo add` does before persisting the entry.
+	cr, err := repo.NewChartRepository(&entry, getter.All(state.envs))
+	if err != nil {
+		return setError(fmt.Errorf("failed to initialize repository client: %w", err))
+	}
+	cr.CachePath = state.envs.RepositoryCache
+	if _, err := cr.DownloadIndexFile(); err != nil {
+		return setError(fmt.Errorf("failed to fetch index for repository %q: %w", entry.Name, err))
+	}
+
+	repoFile.Update(&entry)
+
+	if err := os.MkdirAll(filepath.Dir(state.envs.RepositoryConfig), 0755); err != nil {
+		return setError(fmt.Errorf("failed to create repository config directory: %w", err))
+	}
+	if err := repoFile.WriteFile(state.envs.RepositoryConfig, 0644); err != nil {
+		return setError(fmt.Errorf("failed to write repository config: %w", err))
+	}
+
+	return 0
+}
+
+//export helmpy_repo_remove
+func helmpy_repo_remove(handle C.helmpy_handle, name *C.char) C.int {
+	state, err := getConfig(handle)
+	if err != nil {
+		return setError(err)
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	repoFile, err := loadRepoFile(state.envs.RepositoryConfig)
+	if err != nil {
+		return setError(fmt.Errorf("failed to load repository config: %w", err))
+	}
+
+	nameStr := C.GoString(name)
+	if !repoFile.Remove(nameStr) {
+		return setError(fmt.Errorf("repository %q not found", nameStr))
+	}
+
+	if err := repoFile.WriteFile(state.envs.RepositoryConfig, 0644); err != nil {
+		return setError(fmt.Errorf("failed to write repository config: %w", err))
+	}
+
+	return 0
+}
+
+//export helmpy_repo_update
+func helmpy_repo_update(handle C.helmpy_handle) C.int {
+	state, err := getConfig(handle)
+	if err != nil {
+		return setError(err)
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	repoFile, err := loadRepoFile(state.envs.RepositoryConfig)
+	if err != nil {
+		return setError(fmt.Errorf("failed to load repository config: %w", err))
+	}
+
+	// Update every repository's index concurrently, same as `helm repo
+	// update`, so one slow or unreachable repo doesn't hold up the rest.
+	var (
+		wg       sync.WaitGroup
+		failedMu sync.Mutex
+		failed   []string
+	)
+	for _, entry := range repoFile.Repositories {
+		wg.Add(1)
+		go func(entry *repo.Entry) {
+			defer wg.Done()
+			cr, err := repo.NewChartRepository(entry, getter.All(state.envs))
+			if err != nil {
+				failedMu.Lock()
+				failed = append(failed, entry.Name)
+				failedMu.Unlock()
+				return
+			}
+			cr.CachePath = state.envs.RepositoryCache
+			if _, err := cr.DownloadIndexFile(); err != nil {
+				failedMu.Lock()
+				failed = append(failed, entry.Name)
+				failedMu.Unlock()
+			}
+		}(entry)
+	}
+	wg.Wait()
+
+	if len(failed) > 0 {
+		return setError(fmt.Errorf("failed to update repositories: %v", failed))
+	}
+
+	return 0
+}
+
+// searchScoreThreshold matches the default maximum match score of
+// `helm search repo`: a result is kept only if its score is below it, and a
+// match scores 0 in the chart name and more the further into the indexed
+// line (name, description, keywords) it falls.
+const searchScoreThreshold = 25
+
+// searchResult is one entry in the JSON array returned by helmpy_search_repo.
+type searchResult struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	AppVersion  string `json:"app_version"`
+	Description string `json:"description"`
+}
+
+//export helmpy_search_repo
+func helmpy_search_repo(handle C.helmpy_handle, keyword *C.char, use_regex C.int, version_constraint *C.char, result_json **C.char) C.int {
+	state, err := getConfig(handle)
+	if err != nil {
+		return setError(err)
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	repoFile, err := loadRepoFile(state.envs.RepositoryConfig)
+	if err != nil {
+		return setError(fmt.Errorf("failed to load repository config: %w", err))
+	}
+
+	// Only index every version when a constraint needs to filter across the
+	// full history; otherwise match `helm search repo`'s default of one
+	// result per chart at its latest version.
+	versionConstraintStr := C.GoString(version_constraint)
+	indexAllVersions := versionConstraintStr != ""
+
+	idx := search.NewIndex()
+	for _, entry := range repoFile.Repositories {
+		path := filepath.Join(state.envs.RepositoryCache, helmpath.CacheIndexFile(entry.Name))
+		indexFile, err := repo.LoadIndexFile(path)
+		if err != nil {
+			// Skip repos whose index has not been fetched yet (run
+			// helmpy_repo_update first); this mirrors `helm search repo`.
+			continue
+		}
+		idx.AddRepo(entry.Name, indexFile, indexAllVersions)
+	}
+
+	keywordStr := C.GoString(keyword)
+	var results []*search.Result
+	if keywordStr != "" {
+		results, err = idx.Search(keywordStr, searchScoreThreshold, use_regex != 0)
+		if err != nil {
+			return setError(fmt.Errorf("search failed: %w", err))
+		}
+	} else {
+		results = idx.All()
+	}
+	search.SortScore(results)
+
+	var constraint *semver.Constraints
+	if versionConstraintStr != "" {
+		constraint, err = semver.NewConstraint(versionConstraintStr)
+		if err != nil {
+			return setError(fmt.Errorf("invalid version constraint: %w", err))
+		}
+	}
+
+	entries := make([]searchResult, 0, len(results))
+	for _, r := range results {
+		if constraint != nil {
+			v, err := semver.NewVersion(r.Chart.Version)
+			if err != nil || !constraint.Check(v) {
+				continue
+			}
+		}
+		entries = append(entries, searchResult{
+			Name:        r.Name,
+			Version:     r.Chart.Version,
+			AppVersion:  r.Chart.AppVersion,
+			Description: r.Chart.Description,
+		})
+	}
+
+	resultData, err := json.Marshal(entries)
+	if err != nil {
+		return setError(fmt.Errorf("failed to serialize result: %w", err))
+	}
+
+	*result_json = C.CString(string(resultData))
+	return 0
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 Vantage Compute
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v4/pkg/kube"
+)
+
+// resourceProgress is one JSON line describing the apply status of a single
+// Kubernetes resource, streamed through the log callback as install/upgrade
+// walk a release's manifest so Python does not have to poll the cluster to
+// show progress.
+type resourceProgress struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Phase     string `json:"phase"`
+}
+
+// progressKubeClient wraps the concrete *kube.Client (rather than the
+// kube.Interface it satisfies) so embedding promotes every extension
+// interface Helm type-asserts cfg.KubeClient to (resource Build/BuildTable,
+// waiters, three-way-merge support, ...) unchanged; only the methods
+// overridden below gain progress reporting.
+type progressKubeClient struct {
+	*kube.Client
+	emit func(level int, msg string)
+}
+
+// newProgressKubeClient wraps inner so each Create/Update/Delete/
+// WatchUntilReady call emits JSON progress lines via emit: Create, Update and
+// Delete emit two lines per resource (the in-progress phase, then the
+// outcome), WatchUntilReady one line per resource (the outcome).
+func newProgressKubeClient(inner *kube.Client, emit func(level int, msg string)) *progressKubeClient {
+	return &progressKubeClient{Client: inner, emit: emit}
+}
+
+func (p *progressKubeClient) report(resources kube.ResourceList, phase string) {
+	_ = resources.Visit(func(info *resource.Info, err error) error {
+		if err != nil || info == nil {
+			return nil
+		}
+		line, mErr := json.Marshal(resourceProgress{
+			Kind:      info.Mapping.GroupVersionKind.Kind,
+			Name:      info.Name,
+			Namespace: info.Namespace,
+			Phase:     phase,
+		})
+		if mErr == nil {
+			p.emit(logLevelProgress, string(line))
+		}
+		return nil
+	})
+}
+
+func (p *progressKubeClient) Create(resources kube.ResourceList) (*kube.Result, error) {
+	p.report(resources, "Applying")
+	result, err := p.Client.Create(resources)
+	if err != nil {
+		p.report(resources, "Failed")
+		return result, err
+	}
+	p.report(resources, "Created")
+	return result, err
+}
+
+func (p *progressKubeClient) Update(original, target kube.ResourceList, force bool) (*kube.Result, error) {
+	p.report(target, "Applying")
+	result, err := p.Client.Update(original, target, force)
+	if err != nil {
+		p.report(target, "Failed")
+		return result, err
+	}
+	p.report(target, "Updated")
+	return result, err
+}
+
+func (p *progressKubeClient) WatchUntilReady(resources kube.ResourceList, timeout time.Duration) error {
+	if err := p.Client.WatchUntilReady(resources, timeout); err != nil {
+		p.report(resources, "Failed")
+		return err
+	}
+	p.report(resources, "Ready")
+	return nil
+}
+
+func (p *progressKubeClient) Delete(resources kube.ResourceList) (*kube.Result, []error) {
+	p.report(resources, "Deleting")
+	result, errs := p.Client.Delete(resources)
+	if len(errs) > 0 {
+		p.report(resources, "Failed")
+		return result, errs
+	}
+	p.report(resources, "Deleted")
+	return result, errs
+}
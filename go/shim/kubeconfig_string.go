@@ -15,92 +15,104 @@
 package main
 
 import (
-"strings"
-
-"k8s.io/apimachinery/pkg/api/meta"
-"k8s.io/client-go/discovery"
-"k8s.io/client-go/discovery/cached/memory"
-"k8s.io/client-go/rest"
-"k8s.io/client-go/restmapper"
-"k8s.io/client-go/tools/clientcmd"
-clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
 // kubeconfigStringGetter implements genericclioptions.RESTClientGetter
 // for loading kubeconfig from a string instead of a file.
 type kubeconfigStringGetter struct {
-kubeconfigContent string
-namespace         string
-context           string
-cachedDiscovery   discovery.CachedDiscoveryInterface
+	kubeconfigContent string
+	namespace         string
+	context           string
+	cachedDiscovery   discovery.CachedDiscoveryInterface
 }
 
 // NewKubeconfigStringGetter creates a RESTClientGetter that loads
 // kubeconfig from a YAML string instead of a file path.
 func NewKubeconfigStringGetter(kubeconfigContent, namespace, context string) *kubeconfigStringGetter {
-return &kubeconfigStringGetter{
-kubeconfigContent: kubeconfigContent,
-namespace:         namespace,
-context:           context,
-}
+	return &kubeconfigStringGetter{
+		kubeconfigContent: kubeconfigContent,
+		namespace:         namespace,
+		context:           context,
+	}
 }
 
-// ToRESTConfig returns a REST config from the kubeconfig string content.
+// ToRESTConfig returns a REST config from the kubeconfig string content,
+// going through ToRawKubeConfigLoader so namespace/context overrides and
+// exec-credential plugins (client-go exec auth, short-lived EKS/GKE/AKS
+// tokens) are resolved consistently with the on-disk kubeconfig path.
 func (k *kubeconfigStringGetter) ToRESTConfig() (*rest.Config, error) {
-config, err := clientcmd.RESTConfigFromKubeConfig([]byte(k.kubeconfigContent))
-if err != nil {
-return nil, err
-}
-return config, nil
+	return k.ToRawKubeConfigLoader().ClientConfig()
 }
 
 // ToDiscoveryClient returns a discovery client.
 func (k *kubeconfigStringGetter) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
-if k.cachedDiscovery != nil {
-return k.cachedDiscovery, nil
-}
+	if k.cachedDiscovery != nil {
+		return k.cachedDiscovery, nil
+	}
 
-config, err := k.ToRESTConfig()
-if err != nil {
-return nil, err
-}
+	config, err := k.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
 
-discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
-if err != nil {
-return nil, err
-}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, err
+	}
 
-k.cachedDiscovery = memory.NewMemCacheClient(discoveryClient)
-return k.cachedDiscovery, nil
+	k.cachedDiscovery = memory.NewMemCacheClient(discoveryClient)
+	return k.cachedDiscovery, nil
 }
 
 // ToRESTMapper returns a RESTMapper.
 func (k *kubeconfigStringGetter) ToRESTMapper() (meta.RESTMapper, error) {
-discoveryClient, err := k.ToDiscoveryClient()
-if err != nil {
-return nil, err
-}
+	discoveryClient, err := k.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
 
-mapper := restmapper.NewDeferredDiscoveryRESTMapper(discoveryClient)
-return mapper, nil
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(discoveryClient)
+	return mapper, nil
 }
 
-// ToRawKubeConfigLoader returns a clientcmd.ClientConfig.
+// ToRawKubeConfigLoader returns a clientcmd.ClientConfig built via
+// NewNonInteractiveClientConfig so exec-credential plugins (e.g. the AWS
+// IAM authenticator, gke-gcloud-auth-plugin, kubelogin) get a ConfigAccess
+// to resolve against, matching how client-go drives exec auth for on-disk
+// kubeconfigs.
 func (k *kubeconfigStringGetter) ToRawKubeConfigLoader() clientcmd.ClientConfig {
-config, err := clientcmd.NewClientConfigFromBytes([]byte(k.kubeconfigContent))
-if err != nil {
-// Return a default config on error
-return clientcmd.NewDefaultClientConfig(clientcmdapi.Config{}, &clientcmd.ConfigOverrides{})
-}
-return config
+	rawConfig, err := clientcmd.Load([]byte(k.kubeconfigContent))
+	if err != nil {
+		// Return a default config on error
+		return clientcmd.NewDefaultClientConfig(clientcmdapi.Config{}, &clientcmd.ConfigOverrides{})
+	}
+
+	overrides := &clientcmd.ConfigOverrides{}
+	if k.namespace != "" {
+		overrides.Context.Namespace = k.namespace
+	}
+	if k.context != "" {
+		overrides.CurrentContext = k.context
+	}
+
+	return clientcmd.NewNonInteractiveClientConfig(*rawConfig, k.context, overrides, clientcmd.NewDefaultClientConfigLoadingRules())
 }
 
 // isKubeconfigYAMLContent checks if the string is YAML content rather than a file path.
 // It looks for typical kubeconfig YAML markers.
 func isKubeconfigYAMLContent(s string) bool {
-trimmed := strings.TrimSpace(s)
-return strings.HasPrefix(trimmed, "apiVersion:") ||
-strings.HasPrefix(trimmed, "kind:") ||
-strings.Contains(trimmed, "\nclusters:") ||
-strings.Contains(trimmed, "\ncontexts:")
+	trimmed := strings.TrimSpace(s)
+	return strings.HasPrefix(trimmed, "apiVersion:") ||
+		strings.HasPrefix(trimmed, "kind:") ||
+		strings.Contains(trimmed, "\nclusters:") ||
+		strings.Contains(trimmed, "\ncontexts:")
 }
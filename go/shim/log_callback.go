@@ -0,0 +1,105 @@
+// Copyright 2025 Vantage Compute
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+/*
+#include <stdlib.h>
+
+typedef void (*helmpy_log_cb)(int level, const char* msg, void* userdata);
+
+static inline void helmpy_invoke_log_cb(helmpy_log_cb cb, int level, const char* msg, void* userdata) {
+	cb(level, msg, userdata);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+
+	"helm.sh/helm/v4/pkg/kube"
+)
+
+// Log levels passed to the registered callback. progress lines (individual
+// resource apply events) are reported at logLevelProgress so Python can
+// route them to a different sink than plain Helm.Log() chatter.
+const (
+	logLevelInfo      = 0
+	logLevelWarn      = 1
+	logLevelError     = 2
+	logLevelProgress  = 3
+	logLevelOpStarted = 4
+)
+
+// logCallback holds the C function pointer and userdata registered via
+// helmpy_set_log_callback, plus a helper to invoke it safely from Go.
+type logCallback struct {
+	cb       C.helmpy_log_cb
+	userdata unsafe.Pointer
+}
+
+// emit marshals msg to a C string and invokes the registered callback. It is
+// a no-op if no callback has been registered.
+func (lc *logCallback) emit(level int, msg string) {
+	if lc == nil || lc.cb == nil {
+		return
+	}
+	cMsg := C.CString(msg)
+	defer C.free(unsafe.Pointer(cMsg))
+	C.helmpy_invoke_log_cb(lc.cb, C.int(level), cMsg, lc.userdata)
+}
+
+// Log management
+
+//export helmpy_set_log_callback
+func helmpy_set_log_callback(handle C.helmpy_handle, cb C.helmpy_log_cb, userdata unsafe.Pointer) C.int {
+	state, err := getConfig(handle)
+	if err != nil {
+		return setError(err)
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if cb == nil {
+		state.logCB = nil
+		state.cfg.Log = func(string, ...interface{}) {}
+		return 0
+	}
+
+	state.logCB = &logCallback{cb: cb, userdata: userdata}
+	state.cfg.Log = func(format string, v ...interface{}) {
+		state.logCB.emit(logLevelInfo, fmt.Sprintf(format, v...))
+	}
+
+	// Wrap the Kubernetes client once so resource-by-resource apply progress
+	// is streamed through the same callback at logLevelProgress. The emit
+	// closure reads state.logCB on every call rather than capturing it, so
+	// re-registering (or clearing) the callback re-points the already
+	// installed wrapper instead of leaving it stuck on the first/a stale
+	// *logCallback.
+	if !state.progressWrapped {
+		if kc, ok := state.cfg.KubeClient.(*kube.Client); ok {
+			state.cfg.KubeClient = newProgressKubeClient(kc, func(level int, msg string) {
+				if state.logCB != nil {
+					state.logCB.emit(level, msg)
+				}
+			})
+			state.progressWrapped = true
+		}
+	}
+
+	return 0
+}
@@ -30,18 +30,55 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 	"unsafe"
 
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/chart/v2/loader"
 	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/postrender"
+	"helm.sh/helm/v4/pkg/release"
 )
 
 // Configuration state
 type configState struct {
-	cfg  *action.Configuration
-	envs *cli.EnvSettings
-	mu   sync.Mutex
+	cfg             *action.Configuration
+	envs            *cli.EnvSettings
+	mu              sync.Mutex
+	logCB           *logCallback
+	progressWrapped bool
+
+	// ops holds this config's own in-flight cancellable operations
+	// (map[uint64]context.CancelFunc), so helmpy_cancel can only interrupt
+	// an operation started through this same handle, never another
+	// tenant's.
+	ops sync.Map
+
+	// repoUsername/repoPassword/repoCAFile authenticate private HTTP(S)
+	// chart repositories, set via helmpy_config_create_ex.
+	repoUsername string
+	repoPassword string
+	repoCAFile   string
+
+	// postRenderer mutates rendered manifests before apply, set via
+	// helmpy_config_set_post_renderer.
+	postRenderer postrender.PostRenderer
+}
+
+// applyRepoAuth copies any configured private chart-repo credentials onto a
+// ChartPathOptions before it locates/pulls a chart.
+func (s *configState) applyRepoAuth(co *action.ChartPathOptions) {
+	if s.repoUsername != "" {
+		co.Username = s.repoUsername
+	}
+	if s.repoPassword != "" {
+		co.Password = s.repoPassword
+	}
+	if s.repoCAFile != "" {
+		co.CaFile = s.repoCAFile
+	}
 }
 
 var (
@@ -103,30 +140,58 @@ func helmpy_version_number() C.int {
 
 // Configuration management
 
+// selectRESTClientGetter picks how to authenticate to the cluster from a
+// kubeconfig value that may be a file path, raw YAML content, or absent
+// entirely. It is shared by helmpy_config_create and
+// helmpy_config_create_ex so both constructors support kubeconfig-as-YAML
+// and in-cluster ServiceAccount auth identically.
+func selectRESTClientGetter(kc, kctx string, envs *cli.EnvSettings) genericclioptions.RESTClientGetter {
+	switch {
+	case kc != "" && isKubeconfigYAMLContent(kc):
+		// kubeconfig was passed as raw YAML content (e.g. a Secret value or
+		// a short-lived EKS/GKE/AKS token response) instead of a file path.
+		// clientcmd resolves client-go exec-credential plugin stanzas the
+		// same way for in-memory kubeconfigs as it does for on-disk ones.
+		return NewKubeconfigStringGetter(kc, envs.Namespace(), kctx)
+	case kc == "" && isRunningInCluster():
+		return NewInClusterGetter(envs.Namespace())
+	default:
+		if kc != "" {
+			envs.KubeConfig = kc
+		}
+		return envs.RESTClientGetter()
+	}
+}
+
 //export helmpy_config_create
-func helmpy_config_create(namespace *C.char, kubeconfig *C.char, kubecontext *C.char, handle_out *C.helmpy_handle) C.int {
+func helmpy_config_create(namespace *C.char, kubeconfig *C.char, kubecontext *C.char, driver *C.char, handle_out *C.helmpy_handle) C.int {
 	ns := C.GoString(namespace)
 	kc := C.GoString(kubeconfig)
 	kctx := C.GoString(kubecontext)
+	drv := C.GoString(driver)
+	if drv == "" {
+		// Preserve the previous behavior for callers that don't pass one.
+		drv = os.Getenv("HELM_DRIVER")
+	}
 
-	// Create environment settings
+	// Create environment settings. These hold chart/repo locations and stay
+	// independent of how we authenticate to the cluster below.
 	envs := cli.New()
 	if ns != "" {
 		envs.SetNamespace(ns)
 	}
-	if kc != "" {
-		envs.KubeConfig = kc
-	}
 	if kctx != "" {
 		envs.KubeContext = kctx
 	}
 
+	getter := selectRESTClientGetter(kc, kctx, envs)
+
 	// Create action configuration
 	cfg := new(action.Configuration)
 
-	// Initialize the configuration with Kubernetes settings
-	err := cfg.Init(envs.RESTClientGetter(), envs.Namespace(), os.Getenv("HELM_DRIVER"))
-	if err != nil {
+	// Initialize the configuration with Kubernetes settings and the
+	// explicitly chosen storage driver (secret/configmap/memory/sql).
+	if err := initStorage(cfg, getter, envs.Namespace(), drv, ""); err != nil {
 		return setError(fmt.Errorf("failed to initialize helm config: %w", err))
 	}
 
@@ -158,7 +223,7 @@ func getConfig(handle C.helmpy_handle) (*configState, error) {
 // Install action
 
 //export helmpy_install
-func helmpy_install(handle C.helmpy_handle, release_name *C.char, chart_path *C.char, values_json *C.char, result_json **C.char) C.int {
+func helmpy_install(handle C.helmpy_handle, release_name *C.char, chart_path *C.char, values_json *C.char, op_id_out *C.longlong, result_json **C.char) C.int {
 	state, err := getConfig(handle)
 	if err != nil {
 		return setError(err)
@@ -175,6 +240,9 @@ func helmpy_install(handle C.helmpy_handle, release_name *C.char, chart_path *C.
 	client := action.NewInstall(state.cfg)
 	client.ReleaseName = releaseName
 	client.Namespace = state.envs.Namespace()
+	client.ChartPathOptions.RegistryClient = state.cfg.RegistryClient
+	client.PostRenderer = state.postRenderer
+	state.applyRepoAuth(&client.ChartPathOptions)
 
 	// Locate and load the chart (supports local, OCI, and HTTP)
 	cp, err := client.ChartPathOptions.LocateChart(chartPath, state.envs)
@@ -196,8 +264,23 @@ func helmpy_install(handle C.helmpy_handle, release_name *C.char, chart_path *C.
 		}
 	}
 
+	// Register a cancellable operation so a stuck install can be interrupted
+	// from Python via helmpy_cancel. The id is written to op_id_out for a
+	// caller that inspects it after Run returns, and emitted through the log
+	// callback first so a second thread can catch it while Run is still
+	// blocking and call helmpy_cancel in time.
+	opID, ctx, cancel := beginOperation(state)
+	defer func() {
+		cancel()
+		endOperation(state, opID)
+	}()
+	if op_id_out != nil {
+		*op_id_out = C.longlong(opID)
+	}
+	emitOpID(state, opID)
+
 	// Run the install
-	rel, err := client.Run(chart, values)
+	rel, err := client.Run(ctx, chart, values)
 	if err != nil {
 		return setError(fmt.Errorf("install failed: %w", err))
 	}
@@ -215,7 +298,7 @@ func helmpy_install(handle C.helmpy_handle, release_name *C.char, chart_path *C.
 // Upgrade action
 
 //export helmpy_upgrade
-func helmpy_upgrade(handle C.helmpy_handle, release_name *C.char, chart_path *C.char, values_json *C.char, result_json **C.char) C.int {
+func helmpy_upgrade(handle C.helmpy_handle, release_name *C.char, chart_path *C.char, values_json *C.char, op_id_out *C.longlong, result_json **C.char) C.int {
 	state, err := getConfig(handle)
 	if err != nil {
 		return setError(err)
@@ -231,6 +314,9 @@ func helmpy_upgrade(handle C.helmpy_handle, release_name *C.char, chart_path *C.
 	// Create upgrade action
 	client := action.NewUpgrade(state.cfg)
 	client.Namespace = state.envs.Namespace()
+	client.ChartPathOptions.RegistryClient = state.cfg.RegistryClient
+	client.PostRenderer = state.postRenderer
+	state.applyRepoAuth(&client.ChartPathOptions)
 
 	// Locate and load the chart (supports local, OCI, and HTTP)
 	cp, err := client.ChartPathOptions.LocateChart(chartPath, state.envs)
@@ -252,8 +338,23 @@ func helmpy_upgrade(handle C.helmpy_handle, release_name *C.char, chart_path *C.
 		}
 	}
 
+	// Register a cancellable operation so a stuck upgrade can be interrupted
+	// from Python via helmpy_cancel. The id is written to op_id_out for a
+	// caller that inspects it after Run returns, and emitted through the log
+	// callback first so a second thread can catch it while Run is still
+	// blocking and call helmpy_cancel in time.
+	opID, ctx, cancel := beginOperation(state)
+	defer func() {
+		cancel()
+		endOperation(state, opID)
+	}()
+	if op_id_out != nil {
+		*op_id_out = C.longlong(opID)
+	}
+	emitOpID(state, opID)
+
 	// Run the upgrade
-	rel, err := client.Run(releaseName, chart, values)
+	rel, err := client.Run(ctx, releaseName, chart, values)
 	if err != nil {
 		return setError(fmt.Errorf("upgrade failed: %w", err))
 	}
@@ -271,7 +372,7 @@ func helmpy_upgrade(handle C.helmpy_handle, release_name *C.char, chart_path *C.
 // Uninstall action
 
 //export helmpy_uninstall
-func helmpy_uninstall(handle C.helmpy_handle, release_name *C.char, result_json **C.char) C.int {
+func helmpy_uninstall(handle C.helmpy_handle, release_name *C.char, op_id_out *C.longlong, result_json **C.char) C.int {
 	state, err := getConfig(handle)
 	if err != nil {
 		return setError(err)
@@ -285,8 +386,23 @@ func helmpy_uninstall(handle C.helmpy_handle, release_name *C.char, result_json
 	// Create uninstall action
 	client := action.NewUninstall(state.cfg)
 
+	// Register a cancellable operation so a stuck uninstall can be
+	// interrupted from Python via helmpy_cancel. The id is written to
+	// op_id_out for a caller that inspects it after Run returns, and emitted
+	// through the log callback first so a second thread can catch it while
+	// Run is still blocking and call helmpy_cancel in time.
+	opID, ctx, cancel := beginOperation(state)
+	defer func() {
+		cancel()
+		endOperation(state, opID)
+	}()
+	if op_id_out != nil {
+		*op_id_out = C.longlong(opID)
+	}
+	emitOpID(state, opID)
+
 	// Run the uninstall
-	resp, err := client.Run(releaseName)
+	resp, err := client.Run(ctx, releaseName)
 	if err != nil {
 		return setError(fmt.Errorf("uninstall failed: %w", err))
 	}
@@ -490,6 +606,8 @@ func helmpy_pull(handle C.helmpy_handle, chart_ref *C.char, dest_dir *C.char) C.
 	// Create pull action
 	client := action.NewPull()
 	client.Settings = state.envs
+	client.ChartPathOptions.RegistryClient = state.cfg.RegistryClient
+	state.applyRepoAuth(&client.ChartPathOptions)
 	if destDir != "" {
 		client.DestDir = destDir
 	}
@@ -573,8 +691,46 @@ func helmpy_show_values(handle C.helmpy_handle, chart_path *C.char, result_json
 
 // Test action
 
+// testFilters mirrors the include/exclude hook-name filters accepted by
+// `helm test --filter`, passed in as JSON from Python.
+type testFilters struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// testHookResult is the structured, per-hook outcome returned to Python
+// callers so they can render pytest-style summaries without polling the
+// cluster themselves.
+type testHookResult struct {
+	Name        string `json:"name"`
+	Phase       string `json:"phase"`
+	StartedAt   string `json:"started_at,omitempty"`
+	CompletedAt string `json:"completed_at,omitempty"`
+	Logs        string `json:"logs,omitempty"`
+}
+
+// testResult is the JSON shape returned by helmpy_test. Failed is set when
+// one or more test hooks did not pass; the per-hook Phase still distinguishes
+// which ones, so callers should check Failed rather than the C return code
+// to detect a failed test run.
+type testResult struct {
+	Release *release.Release `json:"release"`
+	Hooks   []testHookResult `json:"hooks"`
+	Failed  bool             `json:"failed,omitempty"`
+}
+
+// isTestHook reports whether hook fires on the "test" event.
+func isTestHook(hook *release.Hook) bool {
+	for _, event := range hook.Events {
+		if event == release.HookTest {
+			return true
+		}
+	}
+	return false
+}
+
 //export helmpy_test
-func helmpy_test(handle C.helmpy_handle, release_name *C.char, result_json **C.char) C.int {
+func helmpy_test(handle C.helmpy_handle, release_name *C.char, filters_json *C.char, timeout_seconds C.int, fetch_logs C.int, op_id_out *C.longlong, result_json **C.char) C.int {
 	state, err := getConfig(handle)
 	if err != nil {
 		return setError(err)
@@ -584,22 +740,88 @@ func helmpy_test(handle C.helmpy_handle, release_name *C.char, result_json **C.c
 	defer state.mu.Unlock()
 
 	releaseName := C.GoString(release_name)
+	filtersJSON := C.GoString(filters_json)
 
 	// Create test action
 	client := action.NewReleaseTesting(state.cfg)
+	if timeout_seconds > 0 {
+		client.Timeout = time.Duration(int64(timeout_seconds)) * time.Second
+	}
 
-	// Run the test
-	rel, err := client.Run(releaseName)
-	if err != nil {
-		return setError(fmt.Errorf("test failed: %w", err))
+	if filtersJSON != "" {
+		var filters testFilters
+		if err := json.Unmarshal([]byte(filtersJSON), &filters); err != nil {
+			return setError(fmt.Errorf("failed to parse filters JSON: %w", err))
+		}
+		client.Filters = map[string][]string{
+			"name":  filters.Include,
+			"!name": filters.Exclude,
+		}
+	}
+
+	// Register a cancellable operation so a stuck test run can be
+	// interrupted from Python via helmpy_cancel. The id is written to
+	// op_id_out for a caller that inspects it after Run returns, and emitted
+	// through the log callback first so a second thread can catch it while
+	// Run is still blocking and call helmpy_cancel in time.
+	opID, ctx, cancel := beginOperation(state)
+	defer func() {
+		cancel()
+		endOperation(state, opID)
+	}()
+	if op_id_out != nil {
+		*op_id_out = C.longlong(opID)
+	}
+	emitOpID(state, opID)
+
+	// Run the test. Helm returns the release alongside a non-nil error when
+	// one or more hooks fail, so keep the release to build the per-hook
+	// summary even on failure.
+	rel, runErr := client.Run(ctx, releaseName)
+	if rel == nil {
+		return setError(fmt.Errorf("test failed: %w", runErr))
+	}
+
+	result := testResult{Release: rel, Failed: runErr != nil}
+	for _, hook := range rel.Hooks {
+		if !isTestHook(hook) {
+			continue
+		}
+
+		hr := testHookResult{
+			Name:  hook.Name,
+			Phase: hook.LastRun.Phase.String(),
+		}
+		if !hook.LastRun.StartedAt.IsZero() {
+			hr.StartedAt = hook.LastRun.StartedAt.Format(time.RFC3339)
+		}
+		if !hook.LastRun.CompletedAt.IsZero() {
+			hr.CompletedAt = hook.LastRun.CompletedAt.Format(time.RFC3339)
+		}
+
+		if fetch_logs != 0 {
+			logs, logErr := action.NewGetPodLogs(state.cfg).Run(hook.Name)
+			if logErr != nil {
+				hr.Logs = fmt.Sprintf("failed to fetch logs: %s", logErr)
+			} else {
+				hr.Logs = logs
+			}
+		}
+
+		result.Hooks = append(result.Hooks, hr)
 	}
 
 	// Serialize result
-	resultData, err := json.Marshal(rel)
+	resultData, err := json.Marshal(result)
 	if err != nil {
 		return setError(fmt.Errorf("failed to serialize result: %w", err))
 	}
 
+	// runErr here only ever reflects one or more test hooks failing (rel is
+	// nil for any other error, handled above), which the per-hook Phase and
+	// the top-level Failed flag already surface; return success so the
+	// caller reads the partial result instead of racing an error code
+	// against an allocated result_json.
 	*result_json = C.CString(string(resultData))
 	return 0
 }
@@ -0,0 +1,84 @@
+// Copyright 2025 Vantage Compute
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+/*
+#include <stdlib.h>
+
+typedef int (*helmpy_postrender_cb)(const char* manifests_in, char** manifests_out, void* userdata);
+
+static inline int helmpy_invoke_postrender_cb(helmpy_postrender_cb cb, const char* manifests_in, char** manifests_out, void* userdata) {
+	return cb(manifests_in, manifests_out, userdata);
+}
+*/
+import "C"
+
+import (
+	"bytes"
+	"fmt"
+	"unsafe"
+
+	"helm.sh/helm/v4/pkg/postrender"
+)
+
+// cPostRenderer implements postrender.PostRenderer by invoking a C callback
+// registered through helmpy_config_set_post_renderer. Helm always calls
+// PostRenderer.Run from inside an action's Run method, which install/
+// upgrade/template already execute under state.mu, so the callback is
+// invoked synchronously without taking the lock itself.
+type cPostRenderer struct {
+	cb       C.helmpy_postrender_cb
+	userdata unsafe.Pointer
+}
+
+func (p *cPostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	in := C.CString(renderedManifests.String())
+	defer C.free(unsafe.Pointer(in))
+
+	var out *C.char
+	rc := C.helmpy_invoke_postrender_cb(p.cb, in, &out, p.userdata)
+	if rc != 0 {
+		return nil, fmt.Errorf("post-renderer callback failed with status %d", int(rc))
+	}
+	if out == nil {
+		return nil, fmt.Errorf("post-renderer callback returned no output")
+	}
+	defer C.free(unsafe.Pointer(out))
+
+	return bytes.NewBufferString(C.GoString(out)), nil
+}
+
+var _ postrender.PostRenderer = (*cPostRenderer)(nil)
+
+// Post-renderer plugin interface
+
+//export helmpy_config_set_post_renderer
+func helmpy_config_set_post_renderer(handle C.helmpy_handle, userdata unsafe.Pointer, render C.helmpy_postrender_cb) C.int {
+	state, err := getConfig(handle)
+	if err != nil {
+		return setError(err)
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if render == nil {
+		state.postRenderer = nil
+		return 0
+	}
+
+	state.postRenderer = &cPostRenderer{cb: render, userdata: userdata}
+	return 0
+}
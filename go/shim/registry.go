@@ -0,0 +1,128 @@
+// Copyright 2025 Vantage Compute
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "C"
+
+import (
+	"fmt"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/registry"
+)
+
+// ensureRegistryClient lazily creates the configuration's OCI registry
+// client so helmpy_registry_login can be called before any install/upgrade
+// has had a chance to create one.
+func ensureRegistryClient(state *configState, plainHTTP bool) error {
+	if state.cfg.RegistryClient != nil {
+		return nil
+	}
+
+	opts := []registry.ClientOption{registry.ClientOptEnableCache(true)}
+	if plainHTTP {
+		opts = append(opts, registry.ClientOptPlainHTTP())
+	}
+
+	rc, err := registry.NewClient(opts...)
+	if err != nil {
+		return fmt.Errorf("failed to create registry client: %w", err)
+	}
+	state.cfg.RegistryClient = rc
+	return nil
+}
+
+// Registry login/logout/push
+
+//export helmpy_registry_login
+func helmpy_registry_login(handle C.helmpy_handle, host *C.char, username *C.char, password *C.char, insecure C.int, ca_file *C.char) C.int {
+	state, err := getConfig(handle)
+	if err != nil {
+		return setError(err)
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if err := ensureRegistryClient(state, insecure != 0); err != nil {
+		return setError(err)
+	}
+
+	// Harbor/GHCR/ECR bearer-token auth follows the same convention as
+	// `docker login`: pass the token as the password with a fixed or blank
+	// username (e.g. "oauth2accesstoken" for GCR/ECR-style registries).
+	loginOpts := []registry.LoginOption{
+		registry.LoginOptBasicAuth(C.GoString(username), C.GoString(password)),
+		registry.LoginOptInsecure(insecure != 0),
+	}
+	if caFile := C.GoString(ca_file); caFile != "" {
+		loginOpts = append(loginOpts, registry.LoginOptTLSClientConfig("", "", caFile))
+	}
+
+	if err := state.cfg.RegistryClient.Login(C.GoString(host), loginOpts...); err != nil {
+		return setError(fmt.Errorf("registry login failed: %w", err))
+	}
+
+	return 0
+}
+
+//export helmpy_registry_logout
+func helmpy_registry_logout(handle C.helmpy_handle, host *C.char) C.int {
+	state, err := getConfig(handle)
+	if err != nil {
+		return setError(err)
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if state.cfg.RegistryClient == nil {
+		return setError(fmt.Errorf("no registry client configured; call helmpy_registry_login first"))
+	}
+
+	if err := state.cfg.RegistryClient.Logout(C.GoString(host)); err != nil {
+		return setError(fmt.Errorf("registry logout failed: %w", err))
+	}
+
+	return 0
+}
+
+//export helmpy_push
+func helmpy_push(handle C.helmpy_handle, chart_tgz_path *C.char, oci_ref *C.char, plain_http C.int, result_json **C.char) C.int {
+	state, err := getConfig(handle)
+	if err != nil {
+		return setError(err)
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if err := ensureRegistryClient(state, plain_http != 0); err != nil {
+		return setError(err)
+	}
+
+	chartPath := C.GoString(chart_tgz_path)
+	ociRef := C.GoString(oci_ref)
+
+	client := action.NewPushWithOpts(action.WithPushConfig(state.cfg), action.WithPlainHTTP(plain_http != 0))
+	client.Settings = state.envs
+	output, err := client.Run(chartPath, ociRef)
+	if err != nil {
+		return setError(fmt.Errorf("push failed: %w", err))
+	}
+
+	*result_json = C.CString(output)
+	return 0
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 Vantage Compute
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/kube"
+	"helm.sh/helm/v4/pkg/storage"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// initStorage mirrors action.Configuration.Init, but takes the storage
+// driver name and SQL DSN as explicit arguments instead of reading
+// HELM_DRIVER/HELM_DRIVER_SQL_CONNECTION_STRING from the environment. This
+// is what lets a single loaded shared library serve multiple tenants with
+// independent release stores concurrently.
+func initStorage(cfg *action.Configuration, getter genericclioptions.RESTClientGetter, namespace, helmDriver, driverDSN string) error {
+	restConfig, err := getter.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("failed to build REST config: %w", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to build Kubernetes clientset: %w", err)
+	}
+
+	logFn := func(format string, v ...interface{}) {
+		log.Printf(format, v...)
+	}
+
+	var store *storage.Storage
+	switch helmDriver {
+	case "", "secret", "secrets":
+		d := driver.NewSecrets(clientset.CoreV1().Secrets(namespace))
+		d.Log = logFn
+		store = storage.Init(d)
+	case "configmap", "configmaps":
+		d := driver.NewConfigMaps(clientset.CoreV1().ConfigMaps(namespace))
+		d.Log = logFn
+		store = storage.Init(d)
+	case "memory":
+		d := driver.NewMemory()
+		store = storage.Init(d)
+	case "sql":
+		d, err := driver.NewSQL(driverDSN, logFn, namespace)
+		if err != nil {
+			return fmt.Errorf("failed to initialize SQL storage driver: %w", err)
+		}
+		store = storage.Init(d)
+	default:
+		return fmt.Errorf("unknown storage driver %q", helmDriver)
+	}
+
+	cfg.RESTClientGetter = getter
+	cfg.KubeClient = kube.New(getter)
+	cfg.Releases = store
+	cfg.Log = logFn
+	return nil
+}
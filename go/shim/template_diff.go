@@ -0,0 +1,399 @@
+// Copyright 2025 Vantage Compute
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
+	"helm.sh/helm/v4/pkg/chartutil"
+	"helm.sh/helm/v4/pkg/release"
+)
+
+// sourceHeaderRe matches the "# Source: <path>" comments Helm prepends to
+// each rendered template within a release's concatenated manifest.
+var sourceHeaderRe = regexp.MustCompile(`(?m)^# Source: (.+)$`)
+
+// splitManifestsBySource splits a release's concatenated manifest back into
+// one entry per source template path.
+func splitManifestsBySource(manifest string) map[string]string {
+	matches := sourceHeaderRe.FindAllStringSubmatchIndex(manifest, -1)
+	result := make(map[string]string, len(matches))
+	for i, m := range matches {
+		start := m[1]
+		end := len(manifest)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		source := manifest[m[2]:m[3]]
+		content := strings.TrimSpace(manifest[start:end])
+		if content == "" {
+			continue
+		}
+		if existing, ok := result[source]; ok {
+			result[source] = existing + "\n---\n" + content
+		} else {
+			result[source] = content
+		}
+	}
+	return result
+}
+
+// templateOptions carries the overrides helmpy_template accepts on top of
+// the usual chart/values pair.
+type templateOptions struct {
+	IncludeCRDs bool     `json:"include_crds,omitempty"`
+	IsUpgrade   bool     `json:"is_upgrade,omitempty"`
+	KubeVersion string   `json:"kube_version,omitempty"`
+	APIVersions []string `json:"api_versions,omitempty"`
+}
+
+// templateResult is the JSON shape returned by helmpy_template.
+type templateResult struct {
+	Manifests map[string]string `json:"manifests"`
+	Hooks     []*release.Hook   `json:"hooks,omitempty"`
+	Notes     string            `json:"notes,omitempty"`
+}
+
+// Template action
+
+//export helmpy_template
+func helmpy_template(handle C.helmpy_handle, release_name *C.char, chart_path *C.char, values_json *C.char, options_json *C.char, result_json **C.char) C.int {
+	state, err := getConfig(handle)
+	if err != nil {
+		return setError(err)
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	releaseName := C.GoString(release_name)
+	chartPath := C.GoString(chart_path)
+	valuesJSON := C.GoString(values_json)
+	optionsJSON := C.GoString(options_json)
+
+	var opts templateOptions
+	if optionsJSON != "" {
+		if err := json.Unmarshal([]byte(optionsJSON), &opts); err != nil {
+			return setError(fmt.Errorf("failed to parse options JSON: %w", err))
+		}
+	}
+
+	// Create install action in client-only dry-run mode so nothing touches
+	// the cluster or the release store.
+	client := action.NewInstall(state.cfg)
+	client.ReleaseName = releaseName
+	if client.ReleaseName == "" {
+		client.ReleaseName = "release-name"
+	}
+	client.Namespace = state.envs.Namespace()
+	client.DryRun = true
+	client.ClientOnly = true
+	client.IncludeCRDs = opts.IncludeCRDs
+	client.IsUpgrade = opts.IsUpgrade
+	client.PostRenderer = state.postRenderer
+	state.applyRepoAuth(&client.ChartPathOptions)
+
+	if opts.KubeVersion != "" {
+		kv, err := chartutil.ParseKubeVersion(opts.KubeVersion)
+		if err != nil {
+			return setError(fmt.Errorf("failed to parse kube version: %w", err))
+		}
+		client.KubeVersion = kv
+	}
+	if len(opts.APIVersions) > 0 {
+		client.APIVersions = chartutil.VersionSet(opts.APIVersions)
+	}
+
+	// Locate and load the chart (supports local, OCI, and HTTP)
+	cp, err := client.ChartPathOptions.LocateChart(chartPath, state.envs)
+	if err != nil {
+		return setError(fmt.Errorf("failed to locate chart: %w", err))
+	}
+
+	chrt, err := loader.Load(cp)
+	if err != nil {
+		return setError(fmt.Errorf("failed to load chart: %w", err))
+	}
+
+	// Parse values
+	var values map[string]interface{}
+	if valuesJSON != "" {
+		if err := json.Unmarshal([]byte(valuesJSON), &values); err != nil {
+			return setError(fmt.Errorf("failed to parse values JSON: %w", err))
+		}
+	}
+
+	rel, err := client.Run(context.Background(), chrt, values)
+	if err != nil {
+		return setError(fmt.Errorf("template failed: %w", err))
+	}
+
+	result := templateResult{
+		Manifests: splitManifestsBySource(rel.Manifest),
+		Hooks:     rel.Hooks,
+	}
+	if rel.Info != nil {
+		result.Notes = rel.Info.Notes
+	}
+
+	resultData, err := json.Marshal(result)
+	if err != nil {
+		return setError(fmt.Errorf("failed to serialize result: %w", err))
+	}
+
+	*result_json = C.CString(string(resultData))
+	return 0
+}
+
+// Diff action
+
+// diffEntry is one changed resource in a helmpy_diff result.
+type diffEntry struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Patch     string `json:"patch"`
+}
+
+// resourceIdentity extracts kind/namespace/name from whichever of the old or
+// new manifest text is non-empty, preferring the new one.
+func resourceIdentity(newText, oldText string) (kind, namespace, name string) {
+	var doc struct {
+		Kind     string `json:"kind"`
+		Metadata struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+	}
+	text := newText
+	if text == "" {
+		text = oldText
+	}
+	if err := yaml.Unmarshal([]byte(text), &doc); err != nil {
+		return "", "", ""
+	}
+	return doc.Kind, doc.Metadata.Namespace, doc.Metadata.Name
+}
+
+// splitDocuments splits one source file's joined content (as produced by
+// splitManifestsBySource, which concatenates multiple YAML documents from
+// the same source with "\n---\n") back into one entry per document, so each
+// resource in a multi-object template file gets its own diffEntry.
+func splitDocuments(text string) []string {
+	if text == "" {
+		return nil
+	}
+	parts := strings.Split(text, "\n---\n")
+	docs := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if trimmed := strings.TrimSpace(p); trimmed != "" {
+			docs = append(docs, trimmed)
+		}
+	}
+	return docs
+}
+
+// resourceKey is the identity resourceIdentity extracts, used to match a
+// resource across the deployed and pending manifests regardless of position
+// within its source file.
+type resourceKey struct {
+	kind, namespace, name string
+}
+
+// unifiedDiff renders a minimal unified diff between oldText and newText,
+// labeled with source.
+func unifiedDiff(source, oldText, newText string) string {
+	oldLines := strings.Split(oldText, "\n")
+	newLines := strings.Split(newText, "\n")
+	if oldText == "" {
+		oldLines = nil
+	}
+	if newText == "" {
+		newLines = nil
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "--- %s (deployed)\n", source)
+	fmt.Fprintf(&buf, "+++ %s (pending)\n", source)
+	for _, op := range diffLines(oldLines, newLines) {
+		buf.WriteString(op)
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+// diffLines produces a naive line-level diff (longest common subsequence)
+// prefixed "-"/"+"/" " in the style of `diff -u`.
+func diffLines(a, b []string) []string {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var out []string
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, " "+a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, "-"+a[i])
+			i++
+		default:
+			out = append(out, "+"+b[j])
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		out = append(out, "-"+a[i])
+	}
+	for ; j < m; j++ {
+		out = append(out, "+"+b[j])
+	}
+	return out
+}
+
+//export helmpy_diff
+func helmpy_diff(handle C.helmpy_handle, release_name *C.char, chart_path *C.char, values_json *C.char, result_json **C.char) C.int {
+	state, err := getConfig(handle)
+	if err != nil {
+		return setError(err)
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	releaseName := C.GoString(release_name)
+	chartPath := C.GoString(chart_path)
+	valuesJSON := C.GoString(values_json)
+
+	// Fetch the last deployed manifest to diff against.
+	current, err := action.NewGet(state.cfg).Run(releaseName)
+	if err != nil {
+		return setError(fmt.Errorf("failed to fetch current release: %w", err))
+	}
+	before := splitManifestsBySource(current.Manifest)
+
+	// Render the pending upgrade without touching the cluster.
+	upgrade := action.NewUpgrade(state.cfg)
+	upgrade.Namespace = state.envs.Namespace()
+	upgrade.DryRun = true
+	upgrade.DisableHooks = true
+	state.applyRepoAuth(&upgrade.ChartPathOptions)
+
+	cp, err := upgrade.ChartPathOptions.LocateChart(chartPath, state.envs)
+	if err != nil {
+		return setError(fmt.Errorf("failed to locate chart: %w", err))
+	}
+
+	chrt, err := loader.Load(cp)
+	if err != nil {
+		return setError(fmt.Errorf("failed to load chart: %w", err))
+	}
+
+	var values map[string]interface{}
+	if valuesJSON != "" {
+		if err := json.Unmarshal([]byte(valuesJSON), &values); err != nil {
+			return setError(fmt.Errorf("failed to parse values JSON: %w", err))
+		}
+	}
+
+	pending, err := upgrade.Run(context.Background(), releaseName, chrt, values)
+	if err != nil {
+		return setError(fmt.Errorf("failed to render pending upgrade: %w", err))
+	}
+	after := splitManifestsBySource(pending.Manifest)
+
+	sources := make(map[string]struct{}, len(before)+len(after))
+	for src := range before {
+		sources[src] = struct{}{}
+	}
+	for src := range after {
+		sources[src] = struct{}{}
+	}
+
+	var entries []diffEntry
+	for src := range sources {
+		oldDocs := splitDocuments(before[src])
+		newDocs := splitDocuments(after[src])
+
+		oldByKey := make(map[resourceKey]string, len(oldDocs))
+		for _, doc := range oldDocs {
+			kind, namespace, name := resourceIdentity("", doc)
+			oldByKey[resourceKey{kind, namespace, name}] = doc
+		}
+		newByKey := make(map[resourceKey]string, len(newDocs))
+		for _, doc := range newDocs {
+			kind, namespace, name := resourceIdentity(doc, "")
+			newByKey[resourceKey{kind, namespace, name}] = doc
+		}
+
+		keys := make(map[resourceKey]struct{}, len(oldByKey)+len(newByKey))
+		for k := range oldByKey {
+			keys[k] = struct{}{}
+		}
+		for k := range newByKey {
+			keys[k] = struct{}{}
+		}
+
+		for key := range keys {
+			oldText, newText := oldByKey[key], newByKey[key]
+			if oldText == newText {
+				continue
+			}
+			entries = append(entries, diffEntry{
+				Kind:      key.kind,
+				Namespace: key.namespace,
+				Name:      key.name,
+				Patch:     unifiedDiff(src, oldText, newText),
+			})
+		}
+	}
+
+	resultData, err := json.Marshal(entries)
+	if err != nil {
+		return setError(fmt.Errorf("failed to serialize result: %w", err))
+	}
+
+	*result_json = C.CString(string(resultData))
+	return 0
+}